@@ -0,0 +1,145 @@
+package match
+
+import (
+	"math"
+	"reflect"
+)
+
+// matchContext carries the per-call state threaded through a recursive comparison:
+// the Matcher supplying per-type equality functions, the Options controlling
+// comparison semantics, and the visited set used for cycle detection.
+type matchContext struct {
+	matcher *Matcher
+	opts    matchOptions
+	visited map[visit]bool
+}
+
+func newMatchContext(m *Matcher, opts []Option) *matchContext {
+	return &matchContext{matcher: m, opts: newMatchOptions(opts), visited: make(map[visit]bool)}
+}
+
+// matches recursively requires deep equality (order-agnostic equality for slices,
+// unless overridden by WithOrderedSlices/WithSliceOrderedForType), deferring to a
+// registered equality function for expected's concrete type when one exists.
+func (ctx *matchContext) matches(expected, actual reflect.Value) (matched bool) {
+	if !expected.IsValid() || !actual.IsValid() {
+		// An invalid Value shows up when, e.g., a map key present in expected is
+		// absent from actual (MapIndex returns the zero Value); treat that as a
+		// presence mismatch rather than calling Type() on it.
+		return expected.IsValid() == actual.IsValid()
+	}
+
+	if expected.Type() != actual.Type() {
+		return false
+	}
+
+	if fn, ok := ctx.matcher.equalities[expected.Type()]; ok {
+		return fn.Call([]reflect.Value{expected, actual})[0].Bool()
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if addr1, ok := pointerOf(expected); ok {
+			if addr2, ok := pointerOf(actual); ok {
+				// Slices sharing a backing array are identical only if their lengths agree.
+				if addr1 == addr2 && (expected.Kind() != reflect.Slice || expected.Len() == actual.Len()) {
+					return true
+				}
+				if addr1 > addr2 {
+					addr1, addr2 = addr2, addr1
+				}
+				v := visit{addr1, addr2, expected.Type()}
+				if ctx.visited[v] {
+					return true
+				}
+				ctx.visited[v] = true
+				// Forget a pair that turns out not to match, so that a later comparison
+				// of the same pair (e.g. another trial of an unordered slice match) is
+				// not taken as a match.
+				defer func() {
+					if !matched {
+						delete(ctx.visited, v)
+					}
+				}()
+			}
+		}
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr:
+		if expected.IsNil() || actual.IsNil() {
+			return expected.IsNil() && actual.IsNil()
+		}
+		return ctx.matches(expected.Elem(), actual.Elem())
+	case reflect.Interface:
+		if expected.IsNil() || actual.IsNil() {
+			return expected.IsNil() && actual.IsNil()
+		}
+		// Unwrap to the concrete dynamic value before recursing; comparing two
+		// interface{} holding e.g. []interface{} directly would fall through to the
+		// default case's == and panic on an uncomparable type.
+		return ctx.matches(expected.Elem(), actual.Elem())
+	case reflect.Float32, reflect.Float64:
+		if ctx.opts.floatTolerance > 0 {
+			return math.Abs(expected.Float()-actual.Float()) <= ctx.opts.floatTolerance
+		}
+		return expected.Interface() == actual.Interface()
+	case reflect.Array:
+		fallthrough
+	case reflect.Slice:
+		if expected.Len() != actual.Len() {
+			return false
+		}
+		if ctx.opts.sliceOrdered(expected.Type()) {
+			for i := 0; i < expected.Len(); i++ {
+				if !ctx.matches(expected.Index(i), actual.Index(i)) {
+					return false
+				}
+			}
+			return true
+		}
+		used := make(map[int]bool)
+		for i := 0; i < expected.Len(); i++ {
+			found := false
+			for j := 0; j < actual.Len(); j++ {
+				if used[j] {
+					continue
+				}
+				if ctx.matches(expected.Index(i), actual.Index(j)) {
+					used[j] = true
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < expected.NumField(); i++ {
+			field := expected.Type().Field(i)
+			if field.PkgPath != "" {
+				// skip unexported fields
+				continue
+			}
+			if ctx.opts.fieldIgnored(expected.Type(), field.Name) {
+				continue
+			}
+			if !ctx.matches(expected.Field(i), actual.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Map:
+		for _, key := range expected.MapKeys() {
+			if !ctx.matches(expected.MapIndex(key), actual.MapIndex(key)) {
+				return false
+			}
+		}
+	default:
+		if !expected.Type().Comparable() {
+			return false
+		}
+		return expected.Interface() == actual.Interface()
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package match
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type cyclicNode struct {
+	Value int
+	Next  *cyclicNode
+}
+
+func TestMatchesTable(t *testing.T) {
+	a := &cyclicNode{Value: 1}
+	a.Next = a
+	b := &cyclicNode{Value: 1}
+	b.Next = b
+
+	var jsonA, jsonB interface{}
+	if err := json.Unmarshal([]byte(`{"items":[1,2,3]}`), &jsonA); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"items":[1,2,3]}`), &jsonB); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		expected  interface{}
+		actual    interface{}
+		wantMatch bool
+	}{
+		{
+			name:      "identical maps",
+			expected:  map[string]interface{}{"x": 1, "y": 2},
+			actual:    map[string]interface{}{"x": 1, "y": 2},
+			wantMatch: true,
+		},
+		{
+			name:      "actual missing a map key",
+			expected:  map[string]interface{}{"x": 1, "y": 2},
+			actual:    map[string]interface{}{"x": 1},
+			wantMatch: false,
+		},
+		{
+			name:      "JSON-unmarshaled nested arrays",
+			expected:  jsonA,
+			actual:    jsonB,
+			wantMatch: true,
+		},
+		{
+			name:      "self-referential cyclic struct",
+			expected:  a,
+			actual:    b,
+			wantMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.expected, tt.actual); got != tt.wantMatch {
+				t.Errorf("Matches(%v, %v) = %v, want %v", tt.expected, tt.actual, got, tt.wantMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package match
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Reason categorizes why a Mismatch was reported.
+type Reason string
+
+const (
+	// TypeMismatch means expected and actual have different types at the same path.
+	TypeMismatch Reason = "TypeMismatch"
+	// LengthMismatch means an array or slice at the same path has a different length.
+	LengthMismatch Reason = "LengthMismatch"
+	// MissingInSet means an expected element (slice entry or map key) has no
+	// corresponding match in actual.
+	MissingInSet Reason = "MissingInSet"
+	// HeaderMissing means an expected HTTP header is absent from the actual response.
+	HeaderMissing Reason = "HeaderMissing"
+	// StatusMismatch means the HTTP response status codes differ.
+	StatusMismatch Reason = "StatusMismatch"
+	// BodyJSONInvalid means a response body expected to be JSON failed to unmarshal.
+	BodyJSONInvalid Reason = "BodyJSONInvalid"
+	// ValueMismatch means two comparable leaf values, or two values compared by a
+	// registered equality function, were found to be unequal.
+	ValueMismatch Reason = "ValueMismatch"
+	// SchemaViolation means a value failed JSON Schema or OpenAPI schema validation;
+	// see MatchesHTTPResponseSchema and MatchesOpenAPIOperation.
+	SchemaViolation Reason = "SchemaViolation"
+)
+
+// Mismatch describes a single point of difference found by Diff or DiffHTTPResponse.
+type Mismatch struct {
+	// Path identifies where the mismatch occurred, e.g. `.Header["Content-Type"][0]`
+	// or `.Body.items[3].id`.
+	Path string
+	// Expected is the expected value at Path, or nil if there was none.
+	Expected interface{}
+	// Actual is the actual value at Path, or nil if there was none (e.g. a missing
+	// header or slice element).
+	Actual interface{}
+	// Reason categorizes the mismatch.
+	Reason Reason
+}
+
+// Diff reports the ways in which actual fails to match expected, using the
+// package-level default Matcher. It returns nil if expected and actual match. See
+// Option for the available ways to adjust the comparison; these are the same options
+// accepted by Matches, so Format(Diff(a, b, opts...)) explains a false
+// Matches(a, b, opts...) result.
+func Diff(expected, actual interface{}, opts ...Option) []Mismatch {
+	return defaultMatcher.Diff(expected, actual, opts...)
+}
+
+// Format renders mismatches as a Gomega-style indented report, suitable for
+// inclusion in a test failure message.
+func Format(mismatches []Mismatch) string {
+	if len(mismatches) == 0 {
+		return "Expected and actual matched"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Expected and actual did not match (%d mismatch", len(mismatches))
+	if len(mismatches) != 1 {
+		b.WriteString("es")
+	}
+	b.WriteString("):\n")
+	for _, m := range mismatches {
+		fmt.Fprintf(&b, "%s: %s\n", m.Path, m.Reason)
+		fmt.Fprintf(&b, "\tExpected: %s\n", formatValue(m.Expected))
+		fmt.Fprintf(&b, "\tActual:   %s\n", formatValue(m.Actual))
+	}
+	return b.String()
+}
+
+// formatValue renders a single Expected/Actual value the way Format wants it. A nil
+// interface value renders as <missing>, whether the value was absent or an untyped
+// nil such as a JSON null; a typed nil such as a nil pointer is shown with its type.
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<missing>"
+	}
+	return fmt.Sprintf("<%T>: %#v", v, v)
+}
+
+// Diff reports the ways in which actual fails to match expected, consulting m's
+// registered equality functions before falling back to the default recursive
+// comparison. It returns nil if expected and actual match. See Option for the
+// available ways to adjust the comparison.
+func (m *Matcher) Diff(expected, actual interface{}, opts ...Option) []Mismatch {
+	return m.diffAt(expected, actual, "", opts)
+}
+
+// diffAt is Diff with an explicit starting path, used internally by
+// DiffHTTPResponse to anchor a sub-comparison (e.g. a single header or the body) at
+// the right place in the reported Mismatch paths.
+func (m *Matcher) diffAt(expected, actual interface{}, path string, opts []Option) []Mismatch {
+	ctx := &diffContext{matcher: m, opts: newMatchOptions(opts), visited: make(map[visit]bool)}
+	return ctx.diff(reflect.ValueOf(expected), reflect.ValueOf(actual), path)
+}
+
+// safeInterface returns v.Interface(), or nil if v is not valid or cannot be
+// interfaced (e.g. an unexported struct field reached through a path we don't
+// otherwise skip).
+func safeInterface(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// diffContext carries the per-call state threaded through a recursive diff: the
+// Matcher supplying per-type equality functions, the Options controlling comparison
+// semantics, and the visited set used for cycle detection. It mirrors matchContext.
+type diffContext struct {
+	matcher *Matcher
+	opts    matchOptions
+	visited map[visit]bool
+}
+
+// diff recursively compares expected and actual, appending a Mismatch for every
+// point of difference found. path tracks the Go/JSON-style path to the values
+// currently being compared.
+func (ctx *diffContext) diff(expected, actual reflect.Value, path string) (result []Mismatch) {
+	if !expected.IsValid() || !actual.IsValid() {
+		if expected.IsValid() == actual.IsValid() {
+			return nil
+		}
+		return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: MissingInSet}}
+	}
+
+	if expected.Type() != actual.Type() {
+		return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: TypeMismatch}}
+	}
+
+	if fn, ok := ctx.matcher.equalities[expected.Type()]; ok {
+		if fn.Call([]reflect.Value{expected, actual})[0].Bool() {
+			return nil
+		}
+		return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if addr1, ok := pointerOf(expected); ok {
+			if addr2, ok := pointerOf(actual); ok {
+				// Slices sharing a backing array are identical only if their lengths agree.
+				if addr1 == addr2 && (expected.Kind() != reflect.Slice || expected.Len() == actual.Len()) {
+					return nil
+				}
+				if addr1 > addr2 {
+					addr1, addr2 = addr2, addr1
+				}
+				v := visit{addr1, addr2, expected.Type()}
+				if ctx.visited[v] {
+					return nil
+				}
+				ctx.visited[v] = true
+				// Forget a pair that turns out not to match, so that a later comparison
+				// of the same pair (e.g. another trial of an unordered slice match) is
+				// not taken as a match.
+				defer func() {
+					if len(result) > 0 {
+						delete(ctx.visited, v)
+					}
+				}()
+			}
+		}
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr:
+		if expected.IsNil() || actual.IsNil() {
+			if expected.IsNil() && actual.IsNil() {
+				return nil
+			}
+			return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+		}
+		return ctx.diff(expected.Elem(), actual.Elem(), path)
+	case reflect.Interface:
+		if expected.IsNil() || actual.IsNil() {
+			if expected.IsNil() && actual.IsNil() {
+				return nil
+			}
+			return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+		}
+		// Unwrap to the concrete dynamic value before recursing; comparing two
+		// interface{} holding e.g. []interface{} directly would fall through to the
+		// default case's != and panic on an uncomparable type.
+		return ctx.diff(expected.Elem(), actual.Elem(), path)
+	case reflect.Float32, reflect.Float64:
+		if ctx.opts.floatTolerance > 0 {
+			if math.Abs(expected.Float()-actual.Float()) <= ctx.opts.floatTolerance {
+				return nil
+			}
+			return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+		}
+		if expected.Interface() != actual.Interface() {
+			return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+		}
+		return nil
+	case reflect.Array:
+		fallthrough
+	case reflect.Slice:
+		if expected.Len() != actual.Len() {
+			return []Mismatch{{Path: path, Expected: expected.Len(), Actual: actual.Len(), Reason: LengthMismatch}}
+		}
+		if ctx.opts.sliceOrdered(expected.Type()) {
+			var mismatches []Mismatch
+			for i := 0; i < expected.Len(); i++ {
+				mismatches = append(mismatches, ctx.diff(expected.Index(i), actual.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+			return mismatches
+		}
+		var mismatches []Mismatch
+		used := make(map[int]bool)
+		for i := 0; i < expected.Len(); i++ {
+			found := false
+			for j := 0; j < actual.Len(); j++ {
+				if used[j] {
+					continue
+				}
+				if len(ctx.diff(expected.Index(i), actual.Index(j), path)) == 0 {
+					used[j] = true
+					found = true
+					break
+				}
+			}
+			if !found {
+				mismatches = append(mismatches, Mismatch{
+					Path:     fmt.Sprintf("%s[%d]", path, i),
+					Expected: safeInterface(expected.Index(i)),
+					Actual:   nil,
+					Reason:   MissingInSet,
+				})
+			}
+		}
+		return mismatches
+	case reflect.Struct:
+		var mismatches []Mismatch
+		for i := 0; i < expected.NumField(); i++ {
+			field := expected.Type().Field(i)
+			if field.PkgPath != "" {
+				// skip unexported fields
+				continue
+			}
+			if ctx.opts.fieldIgnored(expected.Type(), field.Name) {
+				continue
+			}
+			mismatches = append(mismatches, ctx.diff(expected.Field(i), actual.Field(i), path+"."+field.Name)...)
+		}
+		return mismatches
+	case reflect.Map:
+		var mismatches []Mismatch
+		for _, key := range sortedMapKeys(expected) {
+			keyPath := fmt.Sprintf("%s[%q]", path, fmt.Sprint(key.Interface()))
+			if expected.Type() == reflect.TypeOf(map[string]interface{}(nil)) {
+				// Decoded JSON objects use JSON-style segments, e.g. .Body.items[3].id.
+				keyPath = path + "." + key.String()
+			}
+			actualValue := actual.MapIndex(key)
+			if !actualValue.IsValid() {
+				mismatches = append(mismatches, Mismatch{
+					Path:     keyPath,
+					Expected: safeInterface(expected.MapIndex(key)),
+					Actual:   nil,
+					Reason:   MissingInSet,
+				})
+				continue
+			}
+			mismatches = append(mismatches, ctx.diff(expected.MapIndex(key), actualValue, keyPath)...)
+		}
+		return mismatches
+	default:
+		if !expected.Type().Comparable() {
+			return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+		}
+		if safeInterface(expected) != safeInterface(actual) {
+			return []Mismatch{{Path: path, Expected: safeInterface(expected), Actual: safeInterface(actual), Reason: ValueMismatch}}
+		}
+		return nil
+	}
+}
+
+// sortedMapKeys returns v's map keys in a deterministic order so that Diff output is
+// stable across runs.
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
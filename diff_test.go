@@ -0,0 +1,70 @@
+package match
+
+import (
+	"testing"
+)
+
+func TestDiffTable(t *testing.T) {
+	tests := []struct {
+		name         string
+		expected     interface{}
+		actual       interface{}
+		wantMismatch bool
+		wantReason   Reason
+	}{
+		{
+			name:         "identical values match",
+			expected:     map[string]interface{}{"x": 1.0},
+			actual:       map[string]interface{}{"x": 1.0},
+			wantMismatch: false,
+		},
+		{
+			name:         "actual missing a map key",
+			expected:     map[string]interface{}{"x": 1.0, "y": 2.0},
+			actual:       map[string]interface{}{"x": 1.0},
+			wantMismatch: true,
+			wantReason:   MissingInSet,
+		},
+		{
+			name:         "type mismatch",
+			expected:     1,
+			actual:       "1",
+			wantMismatch: true,
+			wantReason:   TypeMismatch,
+		},
+		{
+			name:         "length mismatch",
+			expected:     []int{1, 2, 3},
+			actual:       []int{1, 2},
+			wantMismatch: true,
+			wantReason:   LengthMismatch,
+		},
+		{
+			name:         "nested JSON arrays match without panicking",
+			expected:     map[string]interface{}{"items": []interface{}{1.0, 2.0, 3.0}},
+			actual:       map[string]interface{}{"items": []interface{}{1.0, 2.0, 3.0}},
+			wantMismatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches := Diff(tt.expected, tt.actual)
+			if (len(mismatches) > 0) != tt.wantMismatch {
+				t.Fatalf("Diff(%v, %v) = %v, want mismatch=%v", tt.expected, tt.actual, mismatches, tt.wantMismatch)
+			}
+			if tt.wantMismatch && mismatches[0].Reason != tt.wantReason {
+				t.Errorf("Diff reason = %v, want %v", mismatches[0].Reason, tt.wantReason)
+			}
+			if matched := Matches(tt.expected, tt.actual); matched == tt.wantMismatch {
+				t.Errorf("Matches/Diff disagree: Matches=%v, Diff mismatches=%v", matched, mismatches)
+			}
+		})
+	}
+}
+
+func TestFormatNoMismatches(t *testing.T) {
+	if got := Format(nil); got != "Expected and actual matched" {
+		t.Errorf("Format(nil) = %q", got)
+	}
+}
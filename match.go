@@ -3,18 +3,22 @@ package match
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
-	"reflect"
 )
 
-// MatchesHTTPResponse determines whether two http.Responses match.
-func MatchesHTTPResponse(expected, actual *http.Response) bool {
-	if expected.StatusCode != actual.StatusCode {
+// MatchesHTTPResponse determines whether two http.Responses match. See Option for
+// the available ways to adjust the comparison (ignoring headers or JSON paths,
+// tolerating a status code range, etc.).
+func MatchesHTTPResponse(expected, actual *http.Response, opts ...Option) bool {
+	o := newMatchOptions(opts)
+
+	if !o.statusMatches(expected.StatusCode, actual.StatusCode) {
 		return false
 	}
 
-	if !Matches(expected.Header, actual.Header) {
+	if !Matches(o.filteredHeader(expected.Header), o.filteredHeader(actual.Header), opts...) {
 		return false
 	}
 
@@ -24,7 +28,7 @@ func MatchesHTTPResponse(expected, actual *http.Response) bool {
 		return expErr == actErr
 	}
 
-	if !Matches(expected.Trailer, actual.Trailer) {
+	if !Matches(expected.Trailer, actual.Trailer, opts...) {
 		return false
 	}
 
@@ -37,66 +41,65 @@ func MatchesHTTPResponse(expected, actual *http.Response) bool {
 		return false
 	}
 
-	return Matches(exp, act)
+	o.pruneJSONPaths(exp)
+	o.pruneJSONPaths(act)
+
+	return Matches(exp, act, opts...)
 }
 
-// Matches determines whether two arbitrary interfaces match.
-func Matches(expected, actual interface{}) bool {
-	return matchesValues(reflect.ValueOf(expected), reflect.ValueOf(actual))
+// Matches determines whether two arbitrary interfaces match, using the package-level
+// default Matcher. See Option for the available ways to adjust the comparison.
+func Matches(expected, actual interface{}, opts ...Option) bool {
+	return defaultMatcher.Match(expected, actual, opts...)
 }
 
-// matchesValues recursively requires deep equality (order-agnositic equality for slices).
-func matchesValues(expected, actual reflect.Value) bool {
-	if expected.Type() != actual.Type() {
-		return false
+// DiffHTTPResponse reports the ways in which actual fails to match expected. It
+// returns nil if the responses match under the same semantics as
+// MatchesHTTPResponse, and accepts the same Options, so Format(DiffHTTPResponse(a,
+// b, opts...)) explains a false MatchesHTTPResponse(a, b, opts...) result.
+func DiffHTTPResponse(expected, actual *http.Response, opts ...Option) []Mismatch {
+	o := newMatchOptions(opts)
+
+	if !o.statusMatches(expected.StatusCode, actual.StatusCode) {
+		return []Mismatch{{Path: ".StatusCode", Expected: expected.StatusCode, Actual: actual.StatusCode, Reason: StatusMismatch}}
 	}
-	switch expected.Kind() {
-	case reflect.Ptr:
-		if expected.IsNil() || actual.IsNil() {
-			return expected.IsNil() && actual.IsNil()
-		}
-		return matchesValues(expected.Elem(), actual.Elem())
-	case reflect.Array:
-		fallthrough
-	case reflect.Slice:
-		if expected.Len() != actual.Len() {
-			return false
-		}
-		used := make(map[int]bool)
-		for i := 0; i < expected.Len(); i++ {
-			found := false
-			for j := 0; j < actual.Len(); j++ {
-				if used[j] {
-					continue
-				}
-				if matchesValues(expected.Index(i), actual.Index(j)) {
-					used[j] = true
-					found = true
-					break
-				}
-			}
-			if !found {
-				return false
-			}
+
+	var mismatches []Mismatch
+	expHeader := o.filteredHeader(expected.Header)
+	actHeader := o.filteredHeader(actual.Header)
+	for key, expValues := range expHeader {
+		actValues, ok := actHeader[key]
+		if !ok {
+			mismatches = append(mismatches, Mismatch{Path: fmt.Sprintf(".Header[%q]", key), Expected: expValues, Reason: HeaderMissing})
+			continue
 		}
-	case reflect.Struct:
-		for i := 0; i < expected.NumField(); i++ {
-			if expected.Type().Field(i).PkgPath != "" {
-				// skip unexported fields
-				continue
-			}
-			if !matchesValues(expected.Field(i), actual.Field(i)) {
-				return false
-			}
+		mismatches = append(mismatches, defaultMatcher.diffAt(expValues, actValues, fmt.Sprintf(".Header[%q]", key), opts)...)
+	}
+
+	expBody, expErr := ioutil.ReadAll(expected.Body)
+	actBody, actErr := ioutil.ReadAll(actual.Body)
+	if expErr != nil || actErr != nil {
+		if expErr != actErr {
+			mismatches = append(mismatches, Mismatch{Path: ".Body", Expected: expErr, Actual: actErr, Reason: ValueMismatch})
 		}
-	case reflect.Map:
-		for _, key := range expected.MapKeys() {
-			if !matchesValues(expected.MapIndex(key), actual.MapIndex(key)) {
-				return false
-			}
+		return mismatches
+	}
+
+	mismatches = append(mismatches, defaultMatcher.diffAt(expected.Trailer, actual.Trailer, ".Trailer", opts)...)
+
+	var exp, act interface{}
+	if err := json.Unmarshal(expBody, &exp); err != nil {
+		if string(expBody) != string(actBody) {
+			mismatches = append(mismatches, Mismatch{Path: ".Body", Expected: string(expBody), Actual: string(actBody), Reason: ValueMismatch})
 		}
-	default:
-		return expected.Interface() == actual.Interface()
+		return mismatches
+	}
+	if err := json.Unmarshal(actBody, &act); err != nil {
+		return append(mismatches, Mismatch{Path: ".Body", Expected: exp, Reason: BodyJSONInvalid})
 	}
-	return true
+
+	o.pruneJSONPaths(exp)
+	o.pruneJSONPaths(act)
+
+	return append(mismatches, defaultMatcher.diffAt(exp, act, ".Body", opts)...)
 }
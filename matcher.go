@@ -0,0 +1,107 @@
+package match
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// defaultMatcher is the Matcher used by the package-level Matches and
+// MatchesHTTPResponse functions. Registering a function on it via AddFunc affects
+// every caller of those functions.
+var defaultMatcher = NewMatcher()
+
+// AddFunc registers fn on the package-level default Matcher. See Matcher.AddFunc.
+func AddFunc(fn interface{}) error {
+	return defaultMatcher.AddFunc(fn)
+}
+
+// AddFuncs registers fns on the package-level default Matcher. See Matcher.AddFuncs.
+func AddFuncs(fns ...interface{}) error {
+	return defaultMatcher.AddFuncs(fns...)
+}
+
+// Matcher is a configurable deep-equality comparator. Like the package-level Matches
+// function it recurses structurally (order-agnostic for slices, cycle-safe for
+// recursive graphs), but before doing so for a given concrete type it first consults a
+// table of registered equality functions for that type. This mirrors the semantic
+// equality pattern from Kubernetes' apimachinery "equality" package, and lets callers
+// plug in domain-specific comparisons - time.Time via Equal, tolerant floating-point
+// comparisons, normalized quantities - without forking the package.
+type Matcher struct {
+	equalities map[reflect.Type]reflect.Value
+}
+
+// NewMatcher returns a Matcher with no registered equality functions.
+func NewMatcher() *Matcher {
+	return &Matcher{equalities: map[reflect.Type]reflect.Value{}}
+}
+
+// AddFunc registers fn as the equality function to use for its argument type whenever
+// that type is encountered during matching. fn must have the signature
+// func(T, T) bool for some type T; AddFunc returns an error otherwise. Registering a
+// second function for a type already registered replaces the first.
+func (m *Matcher) AddFunc(fn interface{}) error {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		return fmt.Errorf("match: AddFunc: expected func, got %v", ft)
+	}
+	if ft.NumIn() != 2 || ft.NumOut() != 1 {
+		return fmt.Errorf("match: AddFunc: %v does not have signature func(T, T) bool", ft)
+	}
+	if ft.In(0) != ft.In(1) {
+		return fmt.Errorf("match: AddFunc: %v must take two arguments of the same type", ft)
+	}
+	if ft.Out(0).Kind() != reflect.Bool {
+		return fmt.Errorf("match: AddFunc: %v must return bool", ft)
+	}
+	m.equalities[ft.In(0)] = fv
+	return nil
+}
+
+// AddFuncs registers each of fns via AddFunc, stopping at and returning the first
+// error encountered.
+func (m *Matcher) AddFuncs(fns ...interface{}) error {
+	for _, fn := range fns {
+		if err := m.AddFunc(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match determines whether two arbitrary interfaces match, consulting m's registered
+// equality functions before falling back to the default recursive comparison. See
+// Option for the available ways to adjust that comparison.
+func (m *Matcher) Match(expected, actual interface{}, opts ...Option) bool {
+	ctx := newMatchContext(m, opts)
+	return ctx.matches(reflect.ValueOf(expected), reflect.ValueOf(actual))
+}
+
+// visit identifies a pair of values already under comparison, keyed by address and
+// type. It mirrors the cycle-detection approach used by reflect.DeepEqual so that
+// recursive or self-referential graphs (e.g. doubly-linked lists, shared substructure)
+// terminate instead of recursing forever.
+type visit struct {
+	a1, a2 uintptr
+	typ    reflect.Type
+}
+
+// pointerOf returns the address identifying v for cycle-detection purposes: the
+// underlying pointer for kinds that can introduce a cycle (Ptr, Map, Slice, Chan), or
+// v's own address when it is addressable (e.g. a struct or array field). It reports
+// false when no stable address is available.
+func pointerOf(v reflect.Value) (uintptr, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan:
+		if v.IsNil() {
+			return 0, false
+		}
+		return v.Pointer(), true
+	}
+	if v.CanAddr() {
+		return uintptr(unsafe.Pointer(v.UnsafeAddr())), true
+	}
+	return 0, false
+}
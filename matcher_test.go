@@ -0,0 +1,54 @@
+package match
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatcherAddFunc(t *testing.T) {
+	m := NewMatcher()
+	if err := m.AddFunc(func(a, b time.Time) bool { return a.Equal(b) }); err != nil {
+		t.Fatalf("AddFunc: %v", err)
+	}
+
+	utc := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	elsewhere := utc.In(time.FixedZone("UTC+1", 3600))
+
+	if !m.Match(utc, elsewhere) {
+		t.Errorf("Match(%v, %v) = false, want true via registered time.Time equality", utc, elsewhere)
+	}
+	if m.Match(utc, elsewhere.Add(time.Second)) {
+		t.Errorf("Match with differing instants = true, want false")
+	}
+}
+
+func TestMatcherAddFuncRejectsBadSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   interface{}
+	}{
+		{"not a func", 42},
+		{"wrong arg count", func(a int) bool { return true }},
+		{"mismatched arg types", func(a int, b string) bool { return true }},
+		{"non-bool return", func(a, b int) int { return 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewMatcher().AddFunc(tt.fn); err == nil {
+				t.Errorf("AddFunc(%v) = nil error, want error", tt.fn)
+			}
+		})
+	}
+}
+
+func TestMatcherAddFuncsStopsAtFirstError(t *testing.T) {
+	m := NewMatcher()
+	err := m.AddFuncs(
+		func(a, b int) bool { return a == b },
+		"not a func",
+		func(a, b string) bool { return a == b },
+	)
+	if err == nil {
+		t.Fatal("AddFuncs with an invalid entry = nil error, want error")
+	}
+}
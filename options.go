@@ -0,0 +1,167 @@
+package match
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Option configures how Matches, MatchesHTTPResponse, and Matcher.Match compare
+// values. The zero value of the underlying options applies none of them: slices and
+// arrays are compared as unordered sets, no fields/headers/JSON paths are ignored,
+// floats require exact equality, and the actual status code must equal the expected
+// one.
+type Option func(*matchOptions)
+
+// matchOptions holds the accumulated effect of a set of Options.
+type matchOptions struct {
+	orderedSlices     bool
+	orderedSliceTypes map[reflect.Type]bool
+	ignoredFields     map[reflect.Type]map[string]bool
+	ignoredJSONPaths  map[string]bool
+	floatTolerance    float64
+	ignoredHeaders    map[string]bool
+	statusRangeSet    bool
+	statusMin         int
+	statusMax         int
+}
+
+func newMatchOptions(opts []Option) matchOptions {
+	var o matchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithOrderedSlices makes every slice and array comparison position-sensitive,
+// instead of the default order-agnostic set comparison.
+func WithOrderedSlices() Option {
+	return func(o *matchOptions) { o.orderedSlices = true }
+}
+
+// WithSliceOrderedForType makes slices and arrays of t position-sensitive, leaving
+// the default order-agnostic comparison in place for every other type.
+func WithSliceOrderedForType(t reflect.Type) Option {
+	return func(o *matchOptions) {
+		if o.orderedSliceTypes == nil {
+			o.orderedSliceTypes = map[reflect.Type]bool{}
+		}
+		o.orderedSliceTypes[t] = true
+	}
+}
+
+// IgnoreFields excludes the named fields of structType from comparison.
+func IgnoreFields(structType reflect.Type, fieldNames ...string) Option {
+	return func(o *matchOptions) {
+		if o.ignoredFields == nil {
+			o.ignoredFields = map[reflect.Type]map[string]bool{}
+		}
+		if o.ignoredFields[structType] == nil {
+			o.ignoredFields[structType] = map[string]bool{}
+		}
+		for _, name := range fieldNames {
+			o.ignoredFields[structType][name] = true
+		}
+	}
+}
+
+// IgnoreJSONPaths excludes the given dotted paths (e.g. "$.timestamp",
+// "$.items.id") from the JSON body comparison performed by MatchesHTTPResponse.
+func IgnoreJSONPaths(paths ...string) Option {
+	return func(o *matchOptions) {
+		if o.ignoredJSONPaths == nil {
+			o.ignoredJSONPaths = map[string]bool{}
+		}
+		for _, p := range paths {
+			o.ignoredJSONPaths[p] = true
+		}
+	}
+}
+
+// WithFloatTolerance allows float32/float64 values to differ by up to epsilon and
+// still be considered a match.
+func WithFloatTolerance(epsilon float64) Option {
+	return func(o *matchOptions) { o.floatTolerance = epsilon }
+}
+
+// IgnoreHeaders excludes the named headers from the header comparison performed by
+// MatchesHTTPResponse. Header names are canonicalized as http.CanonicalHeaderKey
+// would, so "content-type" and "Content-Type" are equivalent.
+func IgnoreHeaders(headers ...string) Option {
+	return func(o *matchOptions) {
+		if o.ignoredHeaders == nil {
+			o.ignoredHeaders = map[string]bool{}
+		}
+		for _, h := range headers {
+			o.ignoredHeaders[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+}
+
+// WithStatusRange makes MatchesHTTPResponse accept any actual status code in
+// [min, max], instead of requiring it to equal expected.StatusCode.
+func WithStatusRange(min, max int) Option {
+	return func(o *matchOptions) {
+		o.statusRangeSet = true
+		o.statusMin, o.statusMax = min, max
+	}
+}
+
+// sliceOrdered reports whether slices/arrays of type t should be compared
+// position-sensitively under o.
+func (o *matchOptions) sliceOrdered(t reflect.Type) bool {
+	return o.orderedSlices || o.orderedSliceTypes[t]
+}
+
+// fieldIgnored reports whether the field named name on type t should be skipped.
+func (o *matchOptions) fieldIgnored(t reflect.Type, name string) bool {
+	return o.ignoredFields[t][name]
+}
+
+// statusMatches reports whether actual is an acceptable status code given expected
+// and o's WithStatusRange setting, if any.
+func (o *matchOptions) statusMatches(expected, actual int) bool {
+	if o.statusRangeSet {
+		return actual >= o.statusMin && actual <= o.statusMax
+	}
+	return expected == actual
+}
+
+// filteredHeader returns h with any header named by IgnoreHeaders removed. h is
+// returned unmodified when no headers are ignored.
+func (o *matchOptions) filteredHeader(h http.Header) http.Header {
+	if len(o.ignoredHeaders) == 0 {
+		return h
+	}
+	out := h.Clone()
+	for name := range o.ignoredHeaders {
+		out.Del(name)
+	}
+	return out
+}
+
+// pruneJSONPaths deletes each of the dotted paths in o.ignoredJSONPaths (e.g.
+// "$.timestamp") from the decoded JSON value v, so that they are excluded from a
+// subsequent comparison.
+func (o *matchOptions) pruneJSONPaths(v interface{}) {
+	for path := range o.ignoredJSONPaths {
+		segments := strings.Split(strings.TrimPrefix(path, "$."), ".")
+		deleteJSONPath(v, segments)
+	}
+}
+
+// deleteJSONPath removes the value at segments from v, descending through nested
+// JSON objects (decoded as map[string]interface{}). It is a no-op if v or any
+// intermediate value along segments is not an object.
+func deleteJSONPath(v interface{}, segments []string) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(segments) == 0 {
+		return
+	}
+	if len(segments) == 1 {
+		delete(m, segments[0])
+		return
+	}
+	deleteJSONPath(m[segments[0]], segments[1:])
+}
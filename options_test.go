@@ -0,0 +1,111 @@
+package match
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type optEvent struct {
+	ID        string
+	Timestamp int64
+}
+
+func TestOptionsTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  interface{}
+		actual    interface{}
+		opts      []Option
+		wantMatch bool
+	}{
+		{
+			name:      "IgnoreFields skips the named field",
+			expected:  optEvent{ID: "x", Timestamp: 1},
+			actual:    optEvent{ID: "x", Timestamp: 2},
+			opts:      []Option{IgnoreFields(reflect.TypeOf(optEvent{}), "Timestamp")},
+			wantMatch: true,
+		},
+		{
+			name:      "without IgnoreFields the field still matters",
+			expected:  optEvent{ID: "x", Timestamp: 1},
+			actual:    optEvent{ID: "x", Timestamp: 2},
+			wantMatch: false,
+		},
+		{
+			name:      "WithFloatTolerance allows small deltas",
+			expected:  1.0,
+			actual:    1.0001,
+			opts:      []Option{WithFloatTolerance(0.001)},
+			wantMatch: true,
+		},
+		{
+			name:      "WithFloatTolerance rejects large deltas",
+			expected:  1.0,
+			actual:    1.1,
+			opts:      []Option{WithFloatTolerance(0.001)},
+			wantMatch: false,
+		},
+		{
+			name:      "WithOrderedSlices makes order matter",
+			expected:  []int{1, 2, 3},
+			actual:    []int{3, 2, 1},
+			opts:      []Option{WithOrderedSlices()},
+			wantMatch: false,
+		},
+		{
+			name:      "default slice comparison is order-agnostic",
+			expected:  []int{1, 2, 3},
+			actual:    []int{3, 2, 1},
+			wantMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMatch := Matches(tt.expected, tt.actual, tt.opts...)
+			if gotMatch != tt.wantMatch {
+				t.Errorf("Matches = %v, want %v", gotMatch, tt.wantMatch)
+			}
+			// Diff must agree with Matches under the same Options; these two APIs
+			// previously disagreed because Diff ignored Options entirely.
+			mismatches := Diff(tt.expected, tt.actual, tt.opts...)
+			if (len(mismatches) == 0) != gotMatch {
+				t.Errorf("Matches=%v but Diff mismatches=%v", gotMatch, mismatches)
+			}
+		})
+	}
+}
+
+func nopBody(body string) io.ReadCloser {
+	return ioutil.NopCloser(strings.NewReader(body))
+}
+
+func TestDiffHTTPResponseHonorsOptions(t *testing.T) {
+	newResponse := func(code int, requestID, body string) *http.Response {
+		header := http.Header{}
+		header.Set("X-Request-ID", requestID)
+		return &http.Response{StatusCode: code, Header: header, Body: nopBody(body)}
+	}
+
+	opts := []Option{
+		WithStatusRange(200, 299),
+		IgnoreHeaders("X-Request-ID"),
+		IgnoreJSONPaths("$.timestamp"),
+	}
+
+	expected := newResponse(200, "abc", `{"id":"x","timestamp":1}`)
+	actual := newResponse(204, "def", `{"id":"x","timestamp":2}`)
+	if !MatchesHTTPResponse(expected, actual, opts...) {
+		t.Fatalf("MatchesHTTPResponse = false, want true with status range/ignored header/ignored path")
+	}
+
+	expected2 := newResponse(200, "abc", `{"id":"x","timestamp":1}`)
+	actual2 := newResponse(204, "def", `{"id":"x","timestamp":2}`)
+	if mismatches := DiffHTTPResponse(expected2, actual2, opts...); len(mismatches) != 0 {
+		t.Errorf("DiffHTTPResponse = %v, want no mismatches under the same Options", mismatches)
+	}
+}
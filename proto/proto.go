@@ -0,0 +1,209 @@
+// Package proto extends match's deep-equality comparison to protobuf messages and
+// gRPC statuses. Unlike comparing generated structs directly with match.Matches, it
+// honors field presence semantics (an unset field differs from one explicitly set to
+// its zero value) and lets repeated message fields be compared as sets, so the same
+// test-helper style match.MatchesHTTPResponse gives REST tests is available for gRPC
+// services too.
+package proto
+
+import (
+	"bytes"
+	"fmt"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Option configures how MatchesProto and MatchesGRPCStatus compare values. The zero
+// value applies neither: repeated fields are compared in order, and unknown fields
+// are ignored.
+type Option func(*options)
+
+type options struct {
+	mapKeyField         protoreflect.Name
+	strictUnknownFields bool
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMapKeyField makes repeated message fields compare as sets keyed by the named
+// field, instead of the default element-by-element ordered comparison: an expected
+// element matches an actual element when their key fields are equal and their
+// remaining fields match, regardless of position. This suits repeated fields whose
+// order is not semantically meaningful, such as a list of resources identified by an
+// "id" field.
+func WithMapKeyField(fieldName string) Option {
+	return func(o *options) { o.mapKeyField = protoreflect.Name(fieldName) }
+}
+
+// WithStrictUnknownFields makes MatchesProto require that expected and actual carry
+// identical unrecognized wire data, instead of ignoring it.
+func WithStrictUnknownFields() Option {
+	return func(o *options) { o.strictUnknownFields = true }
+}
+
+// MatchesProto determines whether two protobuf messages match. Unlike proto.Equal, it
+// can compare repeated message fields as sets via WithMapKeyField, and it ignores
+// unknown fields unless WithStrictUnknownFields is set.
+func MatchesProto(expected, actual proto.Message, opts ...Option) bool {
+	return matchesMessage(expected.ProtoReflect(), actual.ProtoReflect(), newOptions(opts))
+}
+
+// MatchesGRPCStatus determines whether two gRPC statuses match: the same code, the
+// same message, and matching details, compared via MatchesProto.
+func MatchesGRPCStatus(expected, actual *status.Status, opts ...Option) bool {
+	if expected.Code() != actual.Code() || expected.Message() != actual.Message() {
+		return false
+	}
+
+	expDetails := expected.Details()
+	actDetails := actual.Details()
+	if len(expDetails) != len(actDetails) {
+		return false
+	}
+	for i := range expDetails {
+		expMsg, expOK := expDetails[i].(proto.Message)
+		actMsg, actOK := actDetails[i].(proto.Message)
+		if expOK != actOK {
+			return false
+		}
+		if !expOK {
+			// Detail failed to unmarshal into a concrete type on one or both sides;
+			// fall back to comparing their string representations.
+			if fmt.Sprint(expDetails[i]) != fmt.Sprint(actDetails[i]) {
+				return false
+			}
+			continue
+		}
+		if !MatchesProto(expMsg, actMsg, opts...) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesMessage compares two messages field-by-field using presence semantics: a
+// field expected sets but actual does not (or vice versa) is a mismatch even if
+// actual's zero value would otherwise compare equal.
+func matchesMessage(expected, actual protoreflect.Message, o options) bool {
+	if expected.Descriptor().FullName() != actual.Descriptor().FullName() {
+		return false
+	}
+
+	match := true
+	expected.Range(func(fd protoreflect.FieldDescriptor, expVal protoreflect.Value) bool {
+		if !actual.Has(fd) || !matchesFieldValue(fd, expVal, actual.Get(fd), o) {
+			match = false
+			return false
+		}
+		return true
+	})
+	if !match {
+		return false
+	}
+
+	actual.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !expected.Has(fd) {
+			match = false
+			return false
+		}
+		return true
+	})
+	if !match {
+		return false
+	}
+
+	if o.strictUnknownFields && !bytes.Equal(expected.GetUnknown(), actual.GetUnknown()) {
+		return false
+	}
+	return true
+}
+
+// matchesFieldValue compares a single field's value according to its cardinality.
+func matchesFieldValue(fd protoreflect.FieldDescriptor, expVal, actVal protoreflect.Value, o options) bool {
+	switch {
+	case fd.IsMap():
+		return matchesMap(fd, expVal.Map(), actVal.Map(), o)
+	case fd.IsList():
+		return matchesList(fd, expVal.List(), actVal.List(), o)
+	default:
+		return matchesSingular(fd, expVal, actVal, o)
+	}
+}
+
+// matchesSingular compares a non-repeated, non-map field value, recursing into
+// matchesMessage for message/group kinds.
+func matchesSingular(fd protoreflect.FieldDescriptor, expVal, actVal protoreflect.Value, o options) bool {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return matchesMessage(expVal.Message(), actVal.Message(), o)
+	case protoreflect.BytesKind:
+		// protoreflect.Value.Interface() returns []byte for BytesKind, which is not
+		// comparable via ==.
+		return bytes.Equal(expVal.Bytes(), actVal.Bytes())
+	default:
+		return expVal.Interface() == actVal.Interface()
+	}
+}
+
+// matchesList compares a repeated field, either element-by-element in order (the
+// default) or as a set keyed by o.mapKeyField.
+func matchesList(fd protoreflect.FieldDescriptor, expList, actList protoreflect.List, o options) bool {
+	if expList.Len() != actList.Len() {
+		return false
+	}
+	if o.mapKeyField != "" && fd.Kind() == protoreflect.MessageKind && fd.Message().Fields().ByName(o.mapKeyField) != nil {
+		return matchesListAsSet(fd, expList, actList, o)
+	}
+	for i := 0; i < expList.Len(); i++ {
+		if !matchesSingular(fd, expList.Get(i), actList.Get(i), o) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesListAsSet compares expList and actList as sets keyed by the field named in
+// o.mapKeyField: an expected element matches an actual element with an equal key
+// field whose remaining fields also match.
+func matchesListAsSet(fd protoreflect.FieldDescriptor, expList, actList protoreflect.List, o options) bool {
+	keyFD := fd.Message().Fields().ByName(o.mapKeyField)
+
+	actByKey := make(map[interface{}]protoreflect.Value, actList.Len())
+	for j := 0; j < actList.Len(); j++ {
+		actByKey[actList.Get(j).Message().Get(keyFD).Interface()] = actList.Get(j)
+	}
+
+	for i := 0; i < expList.Len(); i++ {
+		expVal := expList.Get(i)
+		actVal, ok := actByKey[expVal.Message().Get(keyFD).Interface()]
+		if !ok || !matchesSingular(fd, expVal, actVal, o) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesMap compares a protobuf map field. Map entries are already unordered by
+// definition, so no ordering option applies.
+func matchesMap(fd protoreflect.FieldDescriptor, expMap, actMap protoreflect.Map, o options) bool {
+	if expMap.Len() != actMap.Len() {
+		return false
+	}
+	match := true
+	expMap.Range(func(key protoreflect.MapKey, expVal protoreflect.Value) bool {
+		if !actMap.Has(key) || !matchesSingular(fd.MapValue(), expVal, actMap.Get(key), o) {
+			match = false
+			return false
+		}
+		return true
+	})
+	return match
+}
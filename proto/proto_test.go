@@ -0,0 +1,44 @@
+package proto
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMatchesProtoBytesField(t *testing.T) {
+	expected := wrapperspb.Bytes([]byte("hello"))
+	actual := wrapperspb.Bytes([]byte("hello"))
+	if !MatchesProto(expected, actual) {
+		t.Errorf("MatchesProto on identical bytes fields = false, want true")
+	}
+
+	different := wrapperspb.Bytes([]byte("goodbye"))
+	if MatchesProto(expected, different) {
+		t.Errorf("MatchesProto on differing bytes fields = true, want false")
+	}
+}
+
+func TestMatchesProtoScalarFields(t *testing.T) {
+	if !MatchesProto(wrapperspb.String("x"), wrapperspb.String("x")) {
+		t.Errorf("MatchesProto on identical string fields = false, want true")
+	}
+	if MatchesProto(wrapperspb.String("x"), wrapperspb.String("y")) {
+		t.Errorf("MatchesProto on differing string fields = true, want false")
+	}
+}
+
+func TestMatchesGRPCStatus(t *testing.T) {
+	expected := status.New(codes.NotFound, "not found")
+	actual := status.New(codes.NotFound, "not found")
+	if !MatchesGRPCStatus(expected, actual) {
+		t.Errorf("MatchesGRPCStatus on identical statuses = false, want true")
+	}
+
+	different := status.New(codes.Internal, "boom")
+	if MatchesGRPCStatus(expected, different) {
+		t.Errorf("MatchesGRPCStatus on differing statuses = true, want false")
+	}
+}
@@ -0,0 +1,135 @@
+package match
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// MatchesHTTPResponseSchema validates actual's JSON body against schemaDoc, a JSON
+// Schema document (draft-07 or 2020-12), rather than comparing it to a literal
+// expected response. This is a contract-match mode suitable for property tests and
+// fuzzing, where exact byte-for-byte responses aren't known but the shape of the
+// response is. It returns nil mismatches when the body satisfies the schema.
+func MatchesHTTPResponseSchema(schemaDoc []byte, actual *http.Response) (bool, []Mismatch) {
+	body, err := ioutil.ReadAll(actual.Body)
+	if err != nil {
+		return false, []Mismatch{{Path: ".Body", Reason: BodyJSONInvalid, Actual: err.Error()}}
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return false, []Mismatch{{Path: ".Body", Reason: BodyJSONInvalid, Actual: string(body)}}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schemaDoc)); err != nil {
+		return false, []Mismatch{{Path: ".Body", Reason: SchemaViolation, Actual: err.Error()}}
+	}
+	schema, err := compiler.Compile("schema.json")
+	if err != nil {
+		return false, []Mismatch{{Path: ".Body", Reason: SchemaViolation, Actual: err.Error()}}
+	}
+
+	if err := schema.Validate(payload); err != nil {
+		return false, schemaMismatches(".Body", err)
+	}
+	return true, nil
+}
+
+// MatchesOpenAPIOperation validates actual against the response declared for opID's
+// observed status code in spec: its headers against any declared header schemas, and
+// its body against the declared application/json schema, if any. It returns nil
+// mismatches when actual satisfies the operation's contract.
+func MatchesOpenAPIOperation(spec *openapi3.T, opID string, actual *http.Response) (bool, []Mismatch) {
+	op := findOperationByID(spec, opID)
+	if op == nil {
+		return false, []Mismatch{{Path: ".", Reason: SchemaViolation, Expected: opID, Actual: "operation not found in spec"}}
+	}
+
+	responseRef := op.Responses.Status(actual.StatusCode)
+	if responseRef == nil || responseRef.Value == nil {
+		return false, []Mismatch{{Path: ".StatusCode", Reason: StatusMismatch, Actual: actual.StatusCode}}
+	}
+	response := responseRef.Value
+
+	var mismatches []Mismatch
+	for name, headerRef := range response.Headers {
+		if headerRef.Value == nil || headerRef.Value.Schema == nil || headerRef.Value.Schema.Value == nil {
+			continue
+		}
+		got := actual.Header.Get(name)
+		if got == "" {
+			mismatches = append(mismatches, Mismatch{Path: fmt.Sprintf(".Header[%q]", name), Reason: HeaderMissing})
+			continue
+		}
+		if err := headerRef.Value.Schema.Value.VisitJSON(got); err != nil {
+			mismatches = append(mismatches, Mismatch{Path: fmt.Sprintf(".Header[%q]", name), Reason: SchemaViolation, Actual: err.Error()})
+		}
+	}
+
+	mediaType := response.Content.Get("application/json")
+	if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+		return len(mismatches) == 0, mismatches
+	}
+
+	body, err := ioutil.ReadAll(actual.Body)
+	if err != nil {
+		return false, append(mismatches, Mismatch{Path: ".Body", Reason: BodyJSONInvalid, Actual: err.Error()})
+	}
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return false, append(mismatches, Mismatch{Path: ".Body", Reason: BodyJSONInvalid, Actual: string(body)})
+	}
+	if err := mediaType.Schema.Value.VisitJSON(payload); err != nil {
+		mismatches = append(mismatches, Mismatch{Path: ".Body", Reason: SchemaViolation, Actual: err.Error()})
+	}
+
+	return len(mismatches) == 0, mismatches
+}
+
+// findOperationByID returns the operation in spec whose OperationID matches opID, or
+// nil if none does.
+func findOperationByID(spec *openapi3.T, opID string) *openapi3.Operation {
+	for _, path := range spec.Paths.Map() {
+		for _, op := range path.Operations() {
+			if op.OperationID == opID {
+				return op
+			}
+		}
+	}
+	return nil
+}
+
+// schemaMismatches flattens a jsonschema.ValidationError tree into leaf Mismatches,
+// prefixing each violation's instance location with path.
+func schemaMismatches(path string, err error) []Mismatch {
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []Mismatch{{Path: path, Reason: SchemaViolation, Actual: err.Error()}}
+	}
+
+	var mismatches []Mismatch
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			mismatches = append(mismatches, Mismatch{
+				Path:   path + strings.ReplaceAll(e.InstanceLocation, "/", "."),
+				Reason: SchemaViolation,
+				Actual: e.Message,
+			})
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+	return mismatches
+}
@@ -0,0 +1,62 @@
+package match
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMatchesOpenAPIOperation(t *testing.T) {
+	specDoc := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "t", "version": "1"},
+		"paths": {
+			"/widgets": {
+				"get": {
+					"operationId": "getWidgets",
+					"responses": {
+						"200": {
+							"description": "ok",
+							"content": {
+								"application/json": {
+									"schema": {
+										"type": "object",
+										"required": ["id"],
+										"properties": {"id": {"type": "string"}}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`)
+
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(specDoc)
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		t.Fatalf("spec.Validate: %v", err)
+	}
+
+	resp := &http.Response{StatusCode: 200, Body: nopBody(`{"id":"abc"}`)}
+	ok, mismatches := MatchesOpenAPIOperation(spec, "getWidgets", resp)
+	if !ok {
+		t.Fatalf("MatchesOpenAPIOperation = false, mismatches=%v", mismatches)
+	}
+
+	badResp := &http.Response{StatusCode: 200, Body: nopBody(`{}`)}
+	ok, mismatches = MatchesOpenAPIOperation(spec, "getWidgets", badResp)
+	if ok || len(mismatches) == 0 {
+		t.Fatalf("MatchesOpenAPIOperation on a body missing the required field = %v, %v; want false with mismatches", ok, mismatches)
+	}
+
+	if _, mismatches := MatchesOpenAPIOperation(spec, "noSuchOp", resp); len(mismatches) == 0 {
+		t.Fatalf("MatchesOpenAPIOperation with an unknown operationId should report a mismatch")
+	}
+}
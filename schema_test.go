@@ -0,0 +1,40 @@
+package match
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMatchesHTTPResponseSchema(t *testing.T) {
+	schemaDoc := []byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"type": "string"}
+		}
+	}`)
+
+	tests := []struct {
+		name      string
+		body      string
+		wantMatch bool
+	}{
+		{name: "satisfies schema", body: `{"id":"abc"}`, wantMatch: true},
+		{name: "missing required field", body: `{}`, wantMatch: false},
+		{name: "wrong type", body: `{"id":1}`, wantMatch: false},
+		{name: "invalid JSON", body: `not json`, wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Body: nopBody(tt.body)}
+			ok, mismatches := MatchesHTTPResponseSchema(schemaDoc, resp)
+			if ok != tt.wantMatch {
+				t.Errorf("MatchesHTTPResponseSchema = %v, mismatches=%v, want %v", ok, mismatches, tt.wantMatch)
+			}
+			if !tt.wantMatch && len(mismatches) == 0 {
+				t.Errorf("expected at least one mismatch explaining the failure")
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package match
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ErrMaxBufferExceeded is returned by MatchesHTTPResponseStreaming when comparing a
+// single JSON value would require buffering more than maxBufferBytes.
+var ErrMaxBufferExceeded = errors.New("match: streaming comparison exceeded max buffer size")
+
+// defaultMaxBufferBytes is used by MatchesHTTPResponseStreaming when maxBufferBytes
+// is zero or negative.
+const defaultMaxBufferBytes = 1 << 20 // 1MiB
+
+// MatchesHTTPResponseStreaming determines whether two http.Responses match without
+// buffering either body into memory wholesale, unlike MatchesHTTPResponse. It fails
+// fast on a Content-Length mismatch. For an application/json body it walks both
+// sides' JSON token streams in lock-step, comparing arrays element-by-element
+// (order-sensitive) as it goes and buffering only individual object values, each
+// bounded by maxBufferBytes; for any other content type it falls back to a chunked
+// byte comparison using buffers of maxBufferBytes. Both bodies are always closed.
+//
+// A non-nil error means the comparison could not be completed - a read failed, or a
+// single value exceeded maxBufferBytes - which is distinct from a false result,
+// meaning both bodies were read successfully but differ.
+func MatchesHTTPResponseStreaming(expected, actual *http.Response, maxBufferBytes int) (bool, error) {
+	defer expected.Body.Close()
+	defer actual.Body.Close()
+
+	if maxBufferBytes <= 0 {
+		maxBufferBytes = defaultMaxBufferBytes
+	}
+
+	if expected.StatusCode != actual.StatusCode {
+		return false, nil
+	}
+	if !Matches(expected.Header, actual.Header) {
+		return false, nil
+	}
+	if expected.ContentLength >= 0 && actual.ContentLength >= 0 && expected.ContentLength != actual.ContentLength {
+		return false, nil
+	}
+
+	if isJSONContentType(expected.Header.Get("Content-Type")) {
+		return matchesJSONStreaming(expected.Body, actual.Body, maxBufferBytes)
+	}
+	return matchesBytesStreaming(expected.Body, actual.Body, maxBufferBytes)
+}
+
+// isJSONContentType reports whether contentType names a JSON media type.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// boundedReader wraps an io.Reader so that, once armed via setLimit, it fails with
+// errValueTooLarge rather than letting a caller read more than limit bytes through
+// it. decodeBoundedObject arms it around a single object's worth of json.Decoder
+// reads, so an oversized field is caught as the decoder pulls it off the wire
+// instead of after it has already been decoded in full.
+type boundedReader struct {
+	r         io.Reader
+	remaining int
+}
+
+// errValueTooLarge is returned by boundedReader once its limit is exceeded;
+// decodeBoundedObject passes it on as is, and matchesJSONValue translates it to
+// ErrMaxBufferExceeded.
+var errValueTooLarge = errors.New("match: streaming JSON value exceeded max buffer size")
+
+// setLimit arms b to fail once more than n further bytes are read through it.
+func (b *boundedReader) setLimit(n int) {
+	b.remaining = n
+}
+
+func (b *boundedReader) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, errValueTooLarge
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= n
+	return n, err
+}
+
+// matchesJSONStreaming walks exp and act as JSON token streams in lock-step.
+func matchesJSONStreaming(exp, act io.Reader, maxBufferBytes int) (bool, error) {
+	expBounded := &boundedReader{r: exp}
+	actBounded := &boundedReader{r: act}
+	return matchesJSONValue(json.NewDecoder(expBounded), json.NewDecoder(actBounded), expBounded, actBounded, maxBufferBytes)
+}
+
+// matchesJSONValue compares the next JSON value from each decoder, recursing into
+// arrays element-by-element and buffering objects one at a time via
+// decodeBoundedObject. The initial Token read is armed with maxBufferBytes so that a
+// bare scalar (a lone huge string, say) is bounded the same way an object value is,
+// instead of only objects being checked.
+func matchesJSONValue(expDec, actDec *json.Decoder, expBounded, actBounded *boundedReader, maxBufferBytes int) (bool, error) {
+	expBounded.setLimit(maxBufferBytes)
+	actBounded.setLimit(maxBufferBytes)
+	expTok, expErr := expDec.Token()
+	actTok, actErr := actDec.Token()
+	if errors.Is(expErr, errValueTooLarge) || errors.Is(actErr, errValueTooLarge) {
+		return false, ErrMaxBufferExceeded
+	}
+	if expErr != nil || actErr != nil {
+		if expErr == io.EOF && actErr == io.EOF {
+			return true, nil
+		}
+		if expErr != nil && expErr != io.EOF {
+			return false, fmt.Errorf("match: streaming JSON read (expected body): %w", expErr)
+		}
+		if actErr != nil && actErr != io.EOF {
+			return false, fmt.Errorf("match: streaming JSON read (actual body): %w", actErr)
+		}
+		return false, nil
+	}
+
+	expDelim, expIsDelim := expTok.(json.Delim)
+	actDelim, actIsDelim := actTok.(json.Delim)
+	if expIsDelim != actIsDelim {
+		return false, nil
+	}
+	if !expIsDelim {
+		return expTok == actTok, nil
+	}
+	if expDelim != actDelim {
+		return false, nil
+	}
+
+	switch expDelim {
+	case '[':
+		for expDec.More() {
+			if !actDec.More() {
+				return false, nil
+			}
+			ok, err := matchesJSONValue(expDec, actDec, expBounded, actBounded, maxBufferBytes)
+			if err != nil || !ok {
+				return ok, err
+			}
+		}
+		if actDec.More() {
+			return false, nil
+		}
+		expBounded.setLimit(maxBufferBytes)
+		actBounded.setLimit(maxBufferBytes)
+		if _, err := expDec.Token(); err != nil { // consume ']'
+			return false, fmt.Errorf("match: streaming JSON read (expected body): %w", err)
+		}
+		if _, err := actDec.Token(); err != nil {
+			return false, fmt.Errorf("match: streaming JSON read (actual body): %w", err)
+		}
+		return true, nil
+	case '{':
+		expObj, err := decodeBoundedObject(expDec, expBounded, maxBufferBytes)
+		if err != nil {
+			if errors.Is(err, errValueTooLarge) {
+				return false, ErrMaxBufferExceeded
+			}
+			return false, fmt.Errorf("match: streaming JSON read (expected body): %w", err)
+		}
+		actObj, err := decodeBoundedObject(actDec, actBounded, maxBufferBytes)
+		if err != nil {
+			if errors.Is(err, errValueTooLarge) {
+				return false, ErrMaxBufferExceeded
+			}
+			return false, fmt.Errorf("match: streaming JSON read (actual body): %w", err)
+		}
+		return Matches(expObj, actObj, WithOrderedSlices()), nil
+	default:
+		return false, fmt.Errorf("match: streaming JSON read: unexpected delimiter %q", expDelim)
+	}
+}
+
+// decodeBoundedObject decodes the remainder of a JSON object whose opening '{' has
+// already been consumed from dec, returning errValueTooLarge if doing so would
+// require reading more than maxBufferBytes off the wire. br must be the boundedReader
+// feeding dec, so the bound is enforced as bytes are read rather than by measuring the
+// decoded value afterward.
+func decodeBoundedObject(dec *json.Decoder, br *boundedReader, maxBufferBytes int) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	br.setLimit(maxBufferBytes)
+	defer br.setLimit(math.MaxInt)
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := keyTok.(string)
+
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		obj[key] = value
+	}
+	if _, err := dec.Token(); err != nil { // consume '}'
+		return nil, err
+	}
+	return obj, nil
+}
+
+// matchesBytesStreaming compares exp and act in fixed-size chunks, never holding more
+// than maxBufferBytes of either body in memory at once.
+func matchesBytesStreaming(exp, act io.Reader, maxBufferBytes int) (bool, error) {
+	expBuf := make([]byte, maxBufferBytes)
+	actBuf := make([]byte, maxBufferBytes)
+	for {
+		expN, expErr := io.ReadFull(exp, expBuf)
+		actN, actErr := io.ReadFull(act, actBuf)
+
+		if expErr != nil && expErr != io.EOF && expErr != io.ErrUnexpectedEOF {
+			return false, fmt.Errorf("match: streaming read (expected body): %w", expErr)
+		}
+		if actErr != nil && actErr != io.EOF && actErr != io.ErrUnexpectedEOF {
+			return false, fmt.Errorf("match: streaming read (actual body): %w", actErr)
+		}
+
+		if expN != actN || !bytes.Equal(expBuf[:expN], actBuf[:actN]) {
+			return false, nil
+		}
+
+		expDone := expErr == io.EOF || expErr == io.ErrUnexpectedEOF
+		actDone := actErr == io.EOF || actErr == io.ErrUnexpectedEOF
+		if expDone != actDone {
+			return false, nil
+		}
+		if expDone {
+			return true, nil
+		}
+	}
+}
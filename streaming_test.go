@@ -0,0 +1,81 @@
+package match
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMatchesHTTPResponseStreaming(t *testing.T) {
+	newResponse := func(body string) *http.Response {
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		return &http.Response{StatusCode: 200, Header: header, Body: nopBody(body), ContentLength: int64(len(body))}
+	}
+
+	tests := []struct {
+		name      string
+		expected  string
+		actual    string
+		wantMatch bool
+	}{
+		{name: "identical bodies", expected: `{"items":[1,2,3]}`, actual: `{"items":[1,2,3]}`, wantMatch: true},
+		{name: "different array order is a mismatch", expected: `[1,2,3]`, actual: `[3,2,1]`, wantMatch: false},
+		{name: "different object field value", expected: `{"a":1}`, actual: `{"a":2}`, wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := MatchesHTTPResponseStreaming(newResponse(tt.expected), newResponse(tt.actual), 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantMatch {
+				t.Errorf("MatchesHTTPResponseStreaming = %v, want %v", ok, tt.wantMatch)
+			}
+		})
+	}
+}
+
+// countingReader tracks how many bytes have been read through it, so the test can
+// assert decodeBoundedObject stops near the limit instead of after fully decoding an
+// oversized value.
+type countingReader struct {
+	r     io.Reader
+	total int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.total += n
+	return n, err
+}
+
+func TestOversizedValueStopsNearLimit(t *testing.T) {
+	big := strings.Repeat("x", 10*1024*1024)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "object value", body: `{"data":"` + big + `"}`},
+		{name: "bare top-level scalar", body: `"` + big + `"`},
+		{name: "array element", body: `[1,2,"` + big + `"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &countingReader{r: bytes.NewReader([]byte(tt.body))}
+			ok, err := matchesJSONStreaming(cr, strings.NewReader(tt.body), 1024)
+			if !errors.Is(err, ErrMaxBufferExceeded) {
+				t.Fatalf("expected ErrMaxBufferExceeded, got ok=%v err=%v", ok, err)
+			}
+			if cr.total > 2*1024 {
+				t.Errorf("expected the read to stop near the 1024-byte bound, consumed %d bytes of the wire", cr.total)
+			}
+		})
+	}
+}